@@ -0,0 +1,33 @@
+// Package httpmw holds the HTTP middleware shared across coderd routes:
+// authentication, request logging, and the like. This file covers the
+// piece every authorization-sensitive handler depends on: recovering the
+// rbac.Subject the request was authenticated as.
+package httpmw
+
+import (
+	"context"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+type authorizationContextKey struct{}
+
+// WithAuthorization stores subject on ctx. It is called once, by the
+// session/API-key middleware, before any handler that needs to authorize
+// against the caller runs.
+func WithAuthorization(ctx context.Context, subject rbac.Subject) context.Context {
+	return context.WithValue(ctx, authorizationContextKey{}, subject)
+}
+
+// UserAuthorization returns the rbac.Subject the current request
+// authenticated as. It panics if called outside the authentication
+// middleware chain: every coderd route that reaches a handler needing this
+// must be mounted behind it, so a missing subject is a routing bug, not a
+// runtime condition handlers should branch on.
+func UserAuthorization(ctx context.Context) rbac.Subject {
+	subject, ok := ctx.Value(authorizationContextKey{}).(rbac.Subject)
+	if !ok {
+		panic("developer error: httpmw.UserAuthorization called outside the authentication middleware chain")
+	}
+	return subject
+}
@@ -1,17 +1,18 @@
 package coderd
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
-// LatencyCheck is an endpoint for the web ui to measure latency with.
-// allowAll allows any Origin to get timing information. The allowAll should
-// only be set in dev modes.
-//
-//nolint:revive
-func LatencyCheck(allowAll bool, allowedOrigins ...*url.URL) http.HandlerFunc {
+// timingAllowOrigins computes the comma-joined origin list shared by
+// LatencyCheck and ServerTimingMiddleware for the Timing-Allow-Origin
+// header: without it, browsers silently zero out cross-origin timing
+// details for privacy reasons.
+func timingAllowOrigins(allowAll bool, allowedOrigins []*url.URL) string {
 	allowed := make([]string, 0, len(allowedOrigins))
 	for _, origin := range allowedOrigins {
 		// Allow the origin without a path
@@ -22,7 +23,16 @@ func LatencyCheck(allowAll bool, allowedOrigins ...*url.URL) http.HandlerFunc {
 	if allowAll {
 		allowed = append(allowed, "*")
 	}
-	origins := strings.Join(allowed, ",")
+	return strings.Join(allowed, ",")
+}
+
+// LatencyCheck is an endpoint for the web ui to measure latency with.
+// allowAll allows any Origin to get timing information. The allowAll should
+// only be set in dev modes.
+//
+//nolint:revive
+func LatencyCheck(allowAll bool, allowedOrigins ...*url.URL) http.HandlerFunc {
+	origins := timingAllowOrigins(allowAll, allowedOrigins)
 	return func(rw http.ResponseWriter, r *http.Request) {
 		// Allowing timing information to be shared. This allows the browser
 		// to exclude TLS handshake timing.
@@ -37,3 +47,42 @@ func LatencyCheck(allowAll bool, allowedOrigins ...*url.URL) http.HandlerFunc {
 		_, _ = rw.Write([]byte("OK"))
 	}
 }
+
+// latencyCheckDetail is one named sub-measurement in the detailed latency
+// breakdown, e.g. {Name: "db", DurationMS: 4.2}.
+type latencyCheckDetail struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// LatencyCheckDetailed is the non-browser sibling of LatencyCheck: instead
+// of a Server-Timing header, it returns the same named sub-measurements
+// (dns, tls, authz, db, render, ...) reported via ReportServerTiming as
+// JSON, for CLI and agent callers that can't read response timing headers.
+// It must sit behind ServerTimingMiddleware to have anything to report.
+func LatencyCheckDetailed(allowAll bool, allowedOrigins ...*url.URL) http.HandlerFunc {
+	origins := timingAllowOrigins(allowAll, allowedOrigins)
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Timing-Allow-Origin", origins)
+		rw.Header().Set("Access-Control-Allow-Origin", "*")
+		rw.Header().Set("Content-Type", "application/json")
+
+		st, _ := r.Context().Value(serverTimingKey{}).(*serverTiming)
+		details := []latencyCheckDetail{}
+		if st != nil {
+			st.mu.Lock()
+			details = append(details, latencyCheckDetail{Name: "total", DurationMS: msSince(st.start)})
+			for _, e := range st.entries {
+				details = append(details, latencyCheckDetail{Name: e.name, DurationMS: float64(e.dur.Microseconds()) / 1000})
+			}
+			st.mu.Unlock()
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(rw).Encode(details)
+	}
+}
+
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,80 @@
+package coderd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyCheckSetsTimingAllowOrigin(t *testing.T) {
+	t.Parallel()
+
+	origin, err := url.Parse("https://dashboard.example.com")
+	require.NoError(t, err)
+
+	handler := LatencyCheck(false, origin)
+
+	req := httptest.NewRequest(http.MethodGet, "/latency-check", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "https://dashboard.example.com", rec.Header().Get("Timing-Allow-Origin"))
+	require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestLatencyCheckAllowAll(t *testing.T) {
+	t.Parallel()
+
+	handler := LatencyCheck(true)
+
+	req := httptest.NewRequest(http.MethodGet, "/latency-check", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, "*", rec.Header().Get("Timing-Allow-Origin"))
+}
+
+func TestLatencyCheckDetailedWithoutMiddlewareReturnsEmpty(t *testing.T) {
+	t.Parallel()
+
+	handler := LatencyCheckDetailed(false)
+
+	req := httptest.NewRequest(http.MethodGet, "/latency-check/detailed", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var details []latencyCheckDetail
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&details))
+	require.Empty(t, details, "without ServerTimingMiddleware there's nothing to report")
+}
+
+func TestLatencyCheckDetailedReportsMiddlewareEntries(t *testing.T) {
+	t.Parallel()
+
+	inner := LatencyCheckDetailed(false)
+	handler := ServerTimingMiddleware(false)(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ReportServerTiming(r.Context(), "db", 0)
+		inner.ServeHTTP(rw, r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/latency-check/detailed", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var details []latencyCheckDetail
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&details))
+
+	names := make([]string, 0, len(details))
+	for _, d := range details {
+		names = append(names, d.Name)
+	}
+	require.Contains(t, names, "total")
+	require.Contains(t, names, "db")
+}
@@ -0,0 +1,73 @@
+package coderd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/prepared"
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+func TestPoliciesHandlerUpdateSkipsNoOpWrite(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	broadcaster := prepared.NewBroadcaster()
+	handler := newPoliciesHandler(store, broadcaster)
+
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+	original, err := store.InsertPolicy(context.Background(), tokens.Policy{ID: "policy-1", Name: "reader", Role: role})
+	require.NoError(t, err)
+	startIndex := broadcaster.Index()
+
+	body, err := json.Marshal(map[string]any{"name": original.Name, "description": original.Description, "role": role})
+	require.NoError(t, err)
+
+	req := newPolicyRequest(http.MethodPut, "/api/v2/policies/policy-1", body, "policy-1")
+	rec := httptest.NewRecorder()
+	handler.update(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, startIndex, broadcaster.Index(), "resubmitting an identical policy must not bump the broadcaster")
+}
+
+func TestPoliciesHandlerUpdatePersistsChangeAndBumps(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	broadcaster := prepared.NewBroadcaster()
+	handler := newPoliciesHandler(store, broadcaster)
+
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+	_, err := store.InsertPolicy(context.Background(), tokens.Policy{ID: "policy-1", Name: "reader", Description: "read-only", Role: role})
+	require.NoError(t, err)
+	startIndex := broadcaster.Index()
+
+	body, err := json.Marshal(map[string]any{"name": "reader", "description": "read-only, renamed", "role": role})
+	require.NoError(t, err)
+
+	req := newPolicyRequest(http.MethodPut, "/api/v2/policies/policy-1", body, "policy-1")
+	rec := httptest.NewRecorder()
+	handler.update(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var updated tokens.Policy
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&updated))
+	require.Equal(t, "read-only, renamed", updated.Description)
+	require.Greater(t, broadcaster.Index(), startIndex, "a real change must bump the broadcaster")
+}
+
+func newPolicyRequest(method, target string, body []byte, policyID string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("policyID", policyID)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	return req
+}
@@ -0,0 +1,98 @@
+package coderd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/open-policy-agent/opa/ast"
+
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/prepared"
+)
+
+// defaultLongPollTimeout bounds how long a blocking prepared-query request
+// waits for the residual to change before returning the unchanged result,
+// same purpose as Consul's default blocking-query timeout.
+const defaultLongPollTimeout = 5 * time.Minute
+
+// preparedQueriesHandler serves /api/v2/authz/prepared. Callers POST once to
+// get a handle and the initial residual, then GET with their last-seen
+// index to long-poll for the next change.
+type preparedQueriesHandler struct {
+	cache       *prepared.Cache
+	broadcaster *prepared.Broadcaster
+}
+
+func newPreparedQueriesHandler(cache *prepared.Cache, broadcaster *prepared.Broadcaster) *preparedQueriesHandler {
+	return &preparedQueriesHandler{cache: cache, broadcaster: broadcaster}
+}
+
+// preparedQueryRequest never carries a Subject: it is always the subject the
+// request authenticated as (httpmw.UserAuthorization), never caller-supplied,
+// or any client could compile and long-poll the authorization residual for
+// an arbitrary identity of its choosing.
+type preparedQueryRequest struct {
+	Action     rbac.Action `json:"action"`
+	ObjectType string      `json:"object_type"`
+}
+
+type preparedQueryResponse struct {
+	Handle   prepared.Handle      `json:"handle"`
+	Residual ast.Value            `json:"residual"`
+	Index    prepared.ModifyIndex `json:"index"`
+}
+
+func (h *preparedQueriesHandler) prepare(rw http.ResponseWriter, r *http.Request) {
+	var req preparedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	subject := httpmw.UserAuthorization(r.Context())
+
+	start := time.Now()
+	query, err := h.cache.Prepare(r.Context(), subject, req.Action, req.ObjectType)
+	ReportServerTiming(r.Context(), "authz", time.Since(start))
+	if err != nil {
+		httpError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	httpJSON(rw, http.StatusOK, preparedQueryResponse{Handle: query.Handle, Residual: query.Residual, Index: query.Index})
+}
+
+// poll handles the blocking long-poll. A caller passes its last-seen index
+// via ?index=, and the response either returns immediately with a new
+// residual (if the index is already stale) or blocks until one of the RBAC
+// mutation hooks bumps the broadcaster, up to defaultLongPollTimeout.
+func (h *preparedQueriesHandler) poll(rw http.ResponseWriter, r *http.Request) {
+	var req preparedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	sinceRaw := r.URL.Query().Get("index")
+	since, err := strconv.ParseUint(sinceRaw, 10, 64)
+	if err != nil {
+		httpError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	subject := httpmw.UserAuthorization(r.Context())
+
+	ctx, cancel := context.WithTimeout(r.Context(), defaultLongPollTimeout)
+	defer cancel()
+
+	query, err := h.cache.Get(ctx, prepared.Handle(chi.URLParam(r, "handle")), prepared.ModifyIndex(since), subject, req.Action, req.ObjectType)
+	if err != nil {
+		httpError(rw, http.StatusNotFound, err)
+		return
+	}
+	httpJSON(rw, http.StatusOK, preparedQueryResponse{Handle: query.Handle, Residual: query.Residual, Index: query.Index})
+}
@@ -75,6 +75,15 @@ func regoPartialInputValue(subject Subject, action Action, objectType string) (a
 // the subject, because a new value will be created if the subject changes.
 var subjectASTCache = tlru.New[[32]byte](tlru.ConstantCost[ast.Value], 1<<16)
 
+// InvalidateSubjectASTCache discards every cached subject AST node. Callers
+// that mutate roles, policies, ACLs, or group membership should invoke this
+// (in addition to bumping any prepared-query ModifyIndex) so that a cache
+// entry keyed off unchanged subject content never outlives the policy
+// decision it was built from.
+func InvalidateSubjectASTCache() {
+	subjectASTCache = tlru.New[[32]byte](tlru.ConstantCost[ast.Value], 1<<16)
+}
+
 // regoValue returns the ast.Object representation of the subject.
 func (s Subject) regoValue() (ast.Value, error) {
 	cacheKeyHash := sha256.New()
@@ -96,7 +105,7 @@ func (s Subject) regoValue() (ast.Value, error) {
 	subj := ast.NewObject(
 		[2]*ast.Term{
 			ast.StringTerm("id"),
-			ast.StringTerm(s.ID),
+			ast.StringTerm(normalizeIdentifier(s.ID)),
 		},
 		[2]*ast.Term{
 			ast.StringTerm("roles"),
@@ -108,7 +117,7 @@ func (s Subject) regoValue() (ast.Value, error) {
 		},
 		[2]*ast.Term{
 			ast.StringTerm("groups"),
-			ast.NewTerm(regoSliceString(s.Groups...)),
+			ast.NewTerm(regoSliceString(normalizeIdentifiers(s.Groups)...)),
 		},
 	)
 
@@ -119,11 +128,11 @@ func (s Subject) regoValue() (ast.Value, error) {
 func (z Object) regoValue() ast.Value {
 	userACL := ast.NewObject()
 	for k, v := range z.ACLUserList {
-		userACL.Insert(ast.StringTerm(k), ast.NewTerm(regoSlice(v)))
+		userACL.Insert(ast.StringTerm(normalizeIdentifier(k)), ast.NewTerm(regoSlice(v)))
 	}
 	grpACL := ast.NewObject()
 	for k, v := range z.ACLGroupList {
-		grpACL.Insert(ast.StringTerm(k), ast.NewTerm(regoSlice(v)))
+		grpACL.Insert(ast.StringTerm(normalizeIdentifier(k)), ast.NewTerm(regoSlice(v)))
 	}
 	return ast.NewObject(
 		[2]*ast.Term{
@@ -132,11 +141,11 @@ func (z Object) regoValue() ast.Value {
 		},
 		[2]*ast.Term{
 			ast.StringTerm("owner"),
-			ast.StringTerm(z.Owner),
+			ast.StringTerm(normalizeIdentifier(z.Owner)),
 		},
 		[2]*ast.Term{
 			ast.StringTerm("org_owner"),
-			ast.StringTerm(z.OrgID),
+			ast.StringTerm(normalizeIdentifier(z.OrgID)),
 		},
 		[2]*ast.Term{
 			ast.StringTerm("type"),
@@ -0,0 +1,53 @@
+package rbac
+
+// Action is a verb that can be performed against an Object, such as "read"
+// or "update".
+type Action string
+
+// Permission is the combination of an action and the resource type it
+// applies to. A Permission with Negate set to true removes an otherwise
+// granted action instead of adding one.
+type Permission struct {
+	Negate       bool   `json:"negate"`
+	ResourceType string `json:"resource_type"`
+	Action       Action `json:"action"`
+}
+
+// Scope further restricts a Subject's effective permissions down to the
+// role and allow-list it carries. A Scope is itself expressed as a Role so
+// it can be intersected with the Subject's roles using the same rego logic.
+type Scope struct {
+	Role        Role     `json:"role"`
+	AllowIDList []string `json:"allow_list"`
+}
+
+// Expand is a no-op today: unlike Subject.Roles, Scope.Role is already a
+// concrete Role rather than an identifier. It exists so callers can treat
+// scope resolution the same way as role resolution if Scope ever grows a
+// named, looked-up role.
+func (s Scope) Expand() (Scope, error) {
+	return s, nil
+}
+
+// Object is the resource an authorization check is performed against.
+// ACLUserList and ACLGroupList grant the listed permissions to the keyed
+// user or group, independent of the roles assigned to them elsewhere.
+type Object struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner"`
+	OrgID string `json:"org_owner"`
+	Type  string `json:"type"`
+
+	ACLUserList  map[string][]Action `json:"acl_user_list"`
+	ACLGroupList map[string][]Action `json:"acl_group_list"`
+}
+
+// Subject is the actor performing an action. It is deliberately minimal so
+// it can be cheaply hashed for subjectASTCache: anything that affects the
+// authorization decision must live on this struct.
+type Subject struct {
+	ID     string          `json:"id"`
+	Roles  RoleIdentifiers `json:"roles"`
+	Groups []string        `json:"groups"`
+	Scope  Scope           `json:"scope"`
+}
@@ -0,0 +1,35 @@
+package rbac
+
+import "strings"
+
+// CaseSensitiveIdentifiers opts a deployment back into the pre-existing
+// behavior of comparing owner/org_owner IDs, ACL user/group keys, and group
+// names as raw, case-sensitive strings. It defaults to false: identifiers
+// are normalized to lowercase before they reach the rego policy, so "Alice"
+// and "alice" refer to the same subject.
+//
+// Leave this false unless a deployment is known to rely on case-sensitive
+// identifiers; flipping it only changes how regoValue() builds its input,
+// it does not change what is stored.
+var CaseSensitiveIdentifiers = false
+
+// normalizeIdentifier lowercases id unless CaseSensitiveIdentifiers is set,
+// so that ACL entries and subject/group identifiers compare equal
+// regardless of the case they were created or renamed with.
+func normalizeIdentifier(id string) string {
+	if CaseSensitiveIdentifiers {
+		return id
+	}
+	return strings.ToLower(id)
+}
+
+func normalizeIdentifiers(ids []string) []string {
+	if CaseSensitiveIdentifiers {
+		return ids
+	}
+	normalized := make([]string, len(ids))
+	for i, id := range ids {
+		normalized[i] = normalizeIdentifier(id)
+	}
+	return normalized
+}
@@ -0,0 +1,87 @@
+// Package prepared implements prepared authz queries: a (subject, action,
+// objectType) tuple is partially evaluated once against the RBAC policy,
+// producing a residual that list endpoints can translate into a SQL WHERE
+// clause instead of paying a full OPA evaluation per row. It also supports
+// Consul-style index-based long polling so a caller can block until the
+// residual it holds might have changed.
+package prepared
+
+import (
+	"context"
+	"sync"
+)
+
+// ModifyIndex tracks the generation of RBAC state (roles, policies, ACLs,
+// group membership) that prepared queries were compiled against. Every
+// mutation that could change an authorization decision bumps it by one.
+type ModifyIndex uint64
+
+// Broadcaster lets callers block until ModifyIndex advances past a
+// previously observed value.
+type Broadcaster struct {
+	mu      sync.Mutex
+	index   ModifyIndex
+	waiters map[chan struct{}]struct{}
+}
+
+// NewBroadcaster returns a Broadcaster starting at index 0.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{waiters: make(map[chan struct{}]struct{})}
+}
+
+// WaiterCount returns the number of goroutines currently blocked in
+// WaitChanged. It exists for tests that assert a timed-out or canceled
+// waiter doesn't leak an entry.
+func (b *Broadcaster) WaiterCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.waiters)
+}
+
+// Index returns the current ModifyIndex.
+func (b *Broadcaster) Index() ModifyIndex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index
+}
+
+// Bump advances the index and wakes every caller currently blocked in
+// WaitChanged. Call this from every path that mutates roles, policies,
+// ACLs, or group membership.
+func (b *Broadcaster) Bump() ModifyIndex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.index++
+	for ch := range b.waiters {
+		close(ch)
+	}
+	b.waiters = make(map[chan struct{}]struct{})
+	return b.index
+}
+
+// WaitChanged blocks until the index advances past since or ctx is done,
+// whichever comes first, then returns the current index. Compare the
+// result to since to tell a real change from a timeout/cancellation.
+func (b *Broadcaster) WaitChanged(ctx context.Context, since ModifyIndex) ModifyIndex {
+	b.mu.Lock()
+	if b.index > since {
+		idx := b.index
+		b.mu.Unlock()
+		return idx
+	}
+	ch := make(chan struct{})
+	b.waiters[ch] = struct{}{}
+	b.mu.Unlock()
+
+	select {
+	case <-ch:
+		// Bump already removed ch from b.waiters.
+	case <-ctx.Done():
+		// Nobody is going to close ch now; remove it ourselves so every
+		// timed-out long-poller doesn't leak an entry forever.
+		b.mu.Lock()
+		delete(b.waiters, ch)
+		b.mu.Unlock()
+	}
+	return b.Index()
+}
@@ -0,0 +1,51 @@
+package prepared_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac/prepared"
+)
+
+func TestBroadcasterWaitChangedWakesOnBump(t *testing.T) {
+	t.Parallel()
+
+	b := prepared.NewBroadcaster()
+	since := b.Index()
+
+	done := make(chan prepared.ModifyIndex, 1)
+	go func() {
+		done <- b.WaitChanged(context.Background(), since)
+	}()
+
+	// Give WaitChanged a chance to register its waiter before bumping.
+	time.Sleep(10 * time.Millisecond)
+	bumped := b.Bump()
+
+	select {
+	case idx := <-done:
+		require.Equal(t, bumped, idx)
+	case <-time.After(time.Second):
+		t.Fatal("WaitChanged did not wake up after Bump")
+	}
+}
+
+func TestBroadcasterWaitChangedDoesNotLeakOnTimeout(t *testing.T) {
+	t.Parallel()
+
+	b := prepared.NewBroadcaster()
+	since := b.Index()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	idx := b.WaitChanged(ctx, since)
+	require.Equal(t, since, idx, "no Bump occurred, so the returned index should be unchanged")
+
+	// A canceled/timed-out waiter must remove its own entry; otherwise every
+	// long-poll that times out without an RBAC change leaks one forever.
+	require.Equal(t, 0, b.WaiterCount())
+}
@@ -0,0 +1,132 @@
+package prepared
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+// Evaluator produces the partial-evaluation residual for a (subject,
+// action, objectType) tuple. Production code wraps the rbac Authorizer's
+// compiled OPA module; tests can substitute a fake.
+type Evaluator interface {
+	PartialEvaluate(ctx context.Context, subject rbac.Subject, action rbac.Action, objectType string) (ast.Value, error)
+}
+
+// Handle is the opaque identifier a caller holds on to across polls of the
+// same prepared query.
+type Handle string
+
+// Query is a compiled residual policy for one (subject, action, objectType)
+// tuple, plus the ModifyIndex it was compiled against.
+type Query struct {
+	Handle   Handle
+	Residual ast.Value
+	Index    ModifyIndex
+}
+
+// ErrNotFound is returned when a handle was never registered with Prepare,
+// or the cache entry was evicted (e.g. process restart) since it was.
+var ErrNotFound = xerrors.New("prepared query not found")
+
+// Cache compiles and caches prepared queries, and serves blocking long-poll
+// reads against them. A caller registers once with Prepare, then calls Get
+// with its last-seen Index on every subsequent poll; Get blocks until the
+// residual could have changed or the context is done.
+type Cache struct {
+	eval        Evaluator
+	broadcaster *Broadcaster
+
+	mu    sync.Mutex
+	byKey map[Handle]Query
+}
+
+// NewCache returns a Cache that compiles residuals with eval and tracks
+// staleness via broadcaster.
+func NewCache(eval Evaluator, broadcaster *Broadcaster) *Cache {
+	return &Cache{
+		eval:        eval,
+		broadcaster: broadcaster,
+		byKey:       make(map[Handle]Query),
+	}
+}
+
+// Prepare compiles the residual for the given tuple and returns a handle
+// for use with Get.
+func (c *Cache) Prepare(ctx context.Context, subject rbac.Subject, action rbac.Action, objectType string) (Query, error) {
+	residual, err := c.eval.PartialEvaluate(ctx, subject, action, objectType)
+	if err != nil {
+		return Query{}, xerrors.Errorf("partial evaluate: %w", err)
+	}
+
+	handle, err := handleFor(subject, action, objectType)
+	if err != nil {
+		return Query{}, xerrors.Errorf("compute handle: %w", err)
+	}
+
+	query := Query{
+		Handle:   handle,
+		Residual: residual,
+		Index:    c.broadcaster.Index(),
+	}
+
+	c.mu.Lock()
+	c.byKey[handle] = query
+	c.mu.Unlock()
+
+	return query, nil
+}
+
+// Get returns the prepared query for handle. If sinceIndex is already
+// current, it blocks (honoring ctx's deadline) until the broadcaster's
+// index advances. Whenever the index has moved past sinceIndex, whether
+// before the call or during the wait, it recompiles the residual and
+// returns that. If ctx is done first, it returns the cached query
+// unchanged.
+//
+// handle is only ever honored for the (subject, action, objectType) it was
+// derived from: since handleFor is a bare hash with no server secret,
+// anyone who knows a victim's subject ID could otherwise compute their
+// handle and poll it directly. Get instead recomputes the handle from the
+// caller's own authenticated subject and rejects a mismatch, the same way
+// Prepare minted it in the first place.
+func (c *Cache) Get(ctx context.Context, handle Handle, sinceIndex ModifyIndex, subject rbac.Subject, action rbac.Action, objectType string) (Query, error) {
+	expected, err := handleFor(subject, action, objectType)
+	if err != nil {
+		return Query{}, xerrors.Errorf("compute handle: %w", err)
+	}
+	if expected != handle {
+		return Query{}, xerrors.Errorf("handle %q: %w", handle, ErrNotFound)
+	}
+
+	current := c.broadcaster.WaitChanged(ctx, sinceIndex)
+	if current == sinceIndex {
+		c.mu.Lock()
+		query, ok := c.byKey[handle]
+		c.mu.Unlock()
+		if !ok {
+			return Query{}, xerrors.Errorf("handle %q: %w", handle, ErrNotFound)
+		}
+		return query, nil
+	}
+	return c.Prepare(ctx, subject, action, objectType)
+}
+
+func handleFor(subject rbac.Subject, action rbac.Action, objectType string) (Handle, error) {
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(struct {
+		Subject    rbac.Subject
+		Action     rbac.Action
+		ObjectType string
+	}{subject, action, objectType}); err != nil {
+		return "", err
+	}
+	return Handle(hex.EncodeToString(h.Sum(nil))), nil
+}
@@ -0,0 +1,34 @@
+package prepared_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/prepared"
+)
+
+type fakeEvaluator struct{}
+
+func (fakeEvaluator) PartialEvaluate(_ context.Context, _ rbac.Subject, _ rbac.Action, _ string) (ast.Value, error) {
+	return ast.ArrayTerm().Value, nil
+}
+
+func TestCacheGetRejectsForeignHandle(t *testing.T) {
+	t.Parallel()
+
+	cache := prepared.NewCache(fakeEvaluator{}, prepared.NewBroadcaster())
+	victim := rbac.Subject{ID: "victim"}
+	attacker := rbac.Subject{ID: "attacker"}
+
+	query, err := cache.Prepare(context.Background(), victim, "read", "workspace")
+	require.NoError(t, err)
+
+	// Knowing the victim's handle isn't enough: Get must recompute it from
+	// the caller's own authenticated subject and refuse a mismatch.
+	_, err = cache.Get(context.Background(), query.Handle, query.Index, attacker, "read", "workspace")
+	require.ErrorIs(t, err, prepared.ErrNotFound)
+}
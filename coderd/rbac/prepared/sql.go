@@ -0,0 +1,63 @@
+package prepared
+
+import (
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"golang.org/x/xerrors"
+)
+
+// ToSQL renders a partial-evaluation residual into a SQL WHERE clause list
+// endpoints can splice into their query, instead of evaluating OPA once per
+// row. It understands the shape partial evaluation produces for this
+// policy: a disjunction of conjunctions of `object.<field> == <value>`
+// comparisons. columnFor maps a rego object field (e.g. "owner") to the
+// backing SQL column (e.g. "workspaces.owner_id").
+func ToSQL(residual ast.Value, columnFor func(field string) (string, error)) (where string, args []any, err error) {
+	arr, ok := residual.(*ast.Array)
+	if !ok {
+		return "", nil, xerrors.Errorf("residual is not a disjunction of conjunctions: %T", residual)
+	}
+	if arr.Len() == 0 {
+		// An empty residual means partial evaluation found no way this
+		// subject could ever be authorized for objectType.
+		return "false", nil, nil
+	}
+
+	clauses := make([]string, 0, arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		obj, ok := arr.Elem(i).Value.(ast.Object)
+		if !ok {
+			return "", nil, xerrors.Errorf("conjunction %d is not an object: %T", i, arr.Elem(i).Value)
+		}
+
+		var conjunction []string
+		obj.Foreach(func(k, v *ast.Term) {
+			if err != nil {
+				return
+			}
+			field, ok := k.Value.(ast.String)
+			if !ok {
+				err = xerrors.Errorf("conjunction %d: non-string field %v", i, k.Value)
+				return
+			}
+			column, colErr := columnFor(string(field))
+			if colErr != nil {
+				err = xerrors.Errorf("conjunction %d: %w", i, colErr)
+				return
+			}
+			value, jsonErr := ast.JSON(v.Value)
+			if jsonErr != nil {
+				err = xerrors.Errorf("conjunction %d: field %s: %w", i, field, jsonErr)
+				return
+			}
+			conjunction = append(conjunction, column+" = ?")
+			args = append(args, value)
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "("+strings.Join(conjunction, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args, nil
+}
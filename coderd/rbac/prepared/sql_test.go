@@ -0,0 +1,25 @@
+package prepared_test
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac/prepared"
+)
+
+func TestToSQLExtractsNativeValues(t *testing.T) {
+	t.Parallel()
+
+	residual := ast.MustParseTerm(`[{"owner": "alice"}]`).Value
+
+	columnFor := func(field string) (string, error) {
+		return "workspaces." + field + "_id", nil
+	}
+
+	where, args, err := prepared.ToSQL(residual, columnFor)
+	require.NoError(t, err)
+	require.Equal(t, "(workspaces.owner_id = ?)", where)
+	require.Equal(t, []any{"alice"}, args, "arg must be the native string, not OPA's quoted ast.String form")
+}
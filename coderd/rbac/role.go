@@ -0,0 +1,92 @@
+package rbac
+
+import (
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"golang.org/x/xerrors"
+)
+
+// RoleIdentifier names a Role, optionally scoped to an organization. Site
+// roles leave OrganizationID empty.
+type RoleIdentifier struct {
+	Name           string `json:"name"`
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// RoleIdentifiers is the set of roles assigned to a Subject. It is kept as
+// identifiers rather than expanded Role values so that Subject stays cheap
+// to hash and compare.
+type RoleIdentifiers []RoleIdentifier
+
+// Expand resolves each identifier to its full Role definition.
+func (names RoleIdentifiers) Expand() ([]Role, error) {
+	roles := make([]Role, 0, len(names))
+	for _, name := range names {
+		role, err := RoleByName(name)
+		if err != nil {
+			return nil, xerrors.Errorf("role %q: %w", name.Name, err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// Role is a named bundle of permissions. Site permissions apply instance
+// wide, Org permissions are scoped per organization ID, and User permissions
+// apply only to resources owned by the subject holding the role.
+type Role struct {
+	Name        string                  `json:"name"`
+	DisplayName string                  `json:"display_name"`
+	Site        []Permission            `json:"site"`
+	Org         map[string][]Permission `json:"org"`
+	User        []Permission            `json:"user"`
+
+	// cachedRegoValue memoizes regoValue() for roles that are known to be
+	// static for the lifetime of the process, such as the builtin roles.
+	// See withCachedRegoValue.
+	cachedRegoValue ast.Value
+
+	// cachedHash memoizes Hash() for the same reason cachedRegoValue does.
+	// See withCachedHash.
+	cachedHash *[32]byte
+}
+
+var (
+	roleRegistryMu sync.RWMutex
+	// roleRegistry holds every role definition RoleByName can resolve. There
+	// are no built-in roles in this package; every entry comes from
+	// RegisterRole, which tokens.ResolveSubject calls with the rbac.Role of
+	// each tokens.Policy a token links to, keyed by the policy's ID, each
+	// time it resolves that token. Creating or updating a policy does not
+	// register it. A role that was never registered (e.g. the process
+	// restarted and no token linking the policy has been resolved since)
+	// fails to expand with errRoleNotFound.
+	roleRegistry = make(map[RoleIdentifier]Role)
+)
+
+// RegisterRole adds or replaces role's definition in the registry that
+// RoleByName looks up against, keyed by identifier. Callers that persist
+// roles under their own identifier (such as tokens.Policy) must call this
+// with the same RoleIdentifier they hand out to subjects, whenever the role
+// is loaded or changed, so those subjects can expand it at authorization
+// time.
+func RegisterRole(identifier RoleIdentifier, role Role) {
+	roleRegistryMu.Lock()
+	defer roleRegistryMu.Unlock()
+	roleRegistry[identifier] = role
+}
+
+// RoleByName looks up a role definition registered via RegisterRole by
+// identifier.
+func RoleByName(name RoleIdentifier) (Role, error) {
+	roleRegistryMu.RLock()
+	defer roleRegistryMu.RUnlock()
+	role, ok := roleRegistry[name]
+	if !ok {
+		return Role{}, xerrors.Errorf("role %q: %w", name.Name, errRoleNotFound)
+	}
+	return role, nil
+}
+
+var errRoleNotFound = xerrors.New("role not found")
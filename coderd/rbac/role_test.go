@@ -0,0 +1,37 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleByNameNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := RoleByName(RoleIdentifier{Name: "does-not-exist"})
+	require.ErrorIs(t, err, errRoleNotFound)
+}
+
+func TestRegisterRoleExpand(t *testing.T) {
+	t.Parallel()
+
+	identifier := RoleIdentifier{Name: "test-registered-role"}
+	role := Role{Name: "reader", Site: []Permission{{ResourceType: "workspace", Action: "read"}}}
+	RegisterRole(identifier, role)
+
+	expanded, err := RoleIdentifiers{identifier}.Expand()
+	require.NoError(t, err)
+	require.Equal(t, []Role{role}, expanded)
+}
+
+func TestSubjectRegoValueExpandsRegisteredRole(t *testing.T) {
+	t.Parallel()
+
+	identifier := RoleIdentifier{Name: "test-regovalue-role"}
+	RegisterRole(identifier, Role{Name: "reader", Site: []Permission{{ResourceType: "workspace", Action: "read"}}})
+
+	subject := Subject{ID: "subject-1", Roles: RoleIdentifiers{identifier}}
+	_, err := subject.regoValue()
+	require.NoError(t, err, "regoValue must be able to expand a role registered via RegisterRole")
+}
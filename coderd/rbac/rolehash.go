@@ -0,0 +1,45 @@
+package rbac
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+)
+
+// Hash returns a stable content hash over the role's permission bundle,
+// using the same sha256/JSON scheme as subjectASTCache's cache key. It
+// deliberately excludes cachedRegoValue and cachedHash themselves, so two
+// Roles with identical permissions hash identically regardless of whether
+// either has already been evaluated.
+func (role Role) Hash() [32]byte {
+	if role.cachedHash != nil {
+		return *role.cachedHash
+	}
+	h := sha256.New()
+	_ = json.NewEncoder(h).Encode(struct {
+		Name        string
+		DisplayName string
+		Site        []Permission
+		Org         map[string][]Permission
+		User        []Permission
+	}{role.Name, role.DisplayName, role.Site, role.Org, role.User})
+	return [32]byte(h.Sum(nil))
+}
+
+// withCachedHash returns a copy of the role with cachedHash populated. As
+// with withCachedRegoValue, only use this for roles guaranteed static for
+// the life of the process.
+func (role Role) withCachedHash() Role {
+	tmp := role
+	hash := role.Hash()
+	tmp.cachedHash = &hash
+	return tmp
+}
+
+// ShouldPersist reports whether newRole differs from the role currently
+// stored under storedHash. Callers use this to suppress no-op database
+// writes and the policy reload/subjectASTCache invalidation that would
+// otherwise follow, mirroring the CA-config fix that skips raft applies
+// when a resubmitted config is unchanged.
+func ShouldPersist(storedHash [32]byte, newRole Role) bool {
+	return newRole.Hash() != storedHash
+}
@@ -0,0 +1,40 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// BootstrapTokenDescription marks the token created by Bootstrap, so
+// operators and audit logs can distinguish it from tokens issued later.
+const BootstrapTokenDescription = "acl bootstrap reset token"
+
+// Bootstrap issues a fresh, site-scoped, non-expiring local token and
+// deletes any prior bootstrap token. It is intended for the "reset" flow:
+// an operator who has locked themselves out of every other credential can
+// run this once, out of band (CLI or direct DB access), to regain access.
+//
+// The returned token is local: it must never be replicated, since doing so
+// would hand the reset credential to every replica in the cluster.
+func Bootstrap(ctx context.Context, store Store, bootstrapPolicyID string) (Token, error) {
+	// The prior bootstrap token is Local, so it won't show up in
+	// ListReplicableTokens; look it up by description directly.
+	existing, err := store.ListTokensByDescription(ctx, BootstrapTokenDescription)
+	if err != nil {
+		return Token{}, xerrors.Errorf("list tokens: %w", err)
+	}
+	for _, tok := range existing {
+		if err := store.DeleteToken(ctx, tok.AccessorID); err != nil {
+			return Token{}, xerrors.Errorf("delete prior bootstrap token: %w", err)
+		}
+	}
+
+	token, err := New(BootstrapTokenDescription, []string{bootstrapPolicyID}, true, 0, time.Now())
+	if err != nil {
+		return Token{}, xerrors.Errorf("mint bootstrap token: %w", err)
+	}
+
+	return store.InsertToken(ctx, token)
+}
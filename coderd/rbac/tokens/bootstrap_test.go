@@ -0,0 +1,159 @@
+package tokens_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+// memStore is a minimal in-memory tokens.Store for tests.
+type memStore struct {
+	mu       sync.Mutex
+	byAccID  map[string]tokens.Token
+	policies map[string]tokens.Policy
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		byAccID:  make(map[string]tokens.Token),
+		policies: make(map[string]tokens.Policy),
+	}
+}
+
+func (s *memStore) InsertToken(_ context.Context, token tokens.Token) (tokens.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byAccID[token.AccessorID] = token
+	return token, nil
+}
+
+func (s *memStore) GetTokenByAccessorID(_ context.Context, accessorID string) (tokens.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.byAccID[accessorID]
+	if !ok {
+		return tokens.Token{}, tokens.ErrTokenNotFound
+	}
+	return tok, nil
+}
+
+func (s *memStore) UpdateToken(_ context.Context, token tokens.Token) (tokens.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byAccID[token.AccessorID]; !ok {
+		return tokens.Token{}, tokens.ErrTokenNotFound
+	}
+	s.byAccID[token.AccessorID] = token
+	return token, nil
+}
+
+func (s *memStore) DeleteToken(_ context.Context, accessorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byAccID, accessorID)
+	return nil
+}
+
+func (s *memStore) ListReplicableTokens(_ context.Context) ([]tokens.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []tokens.Token
+	for _, tok := range s.byAccID {
+		if !tok.Local {
+			out = append(out, tok)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) ListTokensByDescription(_ context.Context, description string) ([]tokens.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []tokens.Token
+	for _, tok := range s.byAccID {
+		if tok.Description == description {
+			out = append(out, tok)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) InsertPolicy(_ context.Context, policy tokens.Policy) (tokens.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies[policy.ID] = policy
+	return policy, nil
+}
+
+func (s *memStore) GetPolicyByID(_ context.Context, id string) (tokens.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.policies[id]
+	if !ok {
+		return tokens.Policy{}, tokens.ErrPolicyNotFound
+	}
+	return p, nil
+}
+
+func (s *memStore) UpdatePolicy(_ context.Context, policy tokens.Policy) (tokens.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.policies[policy.ID]; !ok {
+		return tokens.Policy{}, tokens.ErrPolicyNotFound
+	}
+	s.policies[policy.ID] = policy
+	return policy, nil
+}
+
+func (s *memStore) DeletePolicy(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.policies, id)
+	return nil
+}
+
+func (s *memStore) PoliciesByIDs(_ context.Context, ids []string) ([]tokens.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]tokens.Policy, 0, len(ids))
+	for _, id := range ids {
+		p, ok := s.policies[id]
+		if !ok {
+			return nil, xerrors.Errorf("policy %q not found", id)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+var _ tokens.Store = (*memStore)(nil)
+
+func TestBootstrapRevokesPriorLocalToken(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+
+	first, err := tokens.Bootstrap(ctx, store, "policy-1")
+	require.NoError(t, err)
+	require.True(t, first.Local)
+
+	// The first bootstrap token must still be readable before the reset.
+	_, err = store.GetTokenByAccessorID(ctx, first.AccessorID)
+	require.NoError(t, err)
+
+	second, err := tokens.Bootstrap(ctx, store, "policy-1")
+	require.NoError(t, err)
+	require.NotEqual(t, first.AccessorID, second.AccessorID)
+
+	_, err = store.GetTokenByAccessorID(ctx, first.AccessorID)
+	require.ErrorIs(t, err, tokens.ErrTokenNotFound, "prior local bootstrap token must be revoked on reset")
+
+	_, err = store.GetTokenByAccessorID(ctx, second.AccessorID)
+	require.NoError(t, err)
+}
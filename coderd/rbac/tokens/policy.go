@@ -0,0 +1,41 @@
+package tokens
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+// UpdatePolicyIfChanged persists policy only if it differs from what is
+// already stored: either its role definition (compared via rbac.ShouldPersist
+// against the stored content hash) or its Name/Description metadata. This
+// avoids a write (and the policy reload it would trigger) only when there is
+// truly nothing to persist — a Name or Description edit with an unchanged
+// role must still reach the store.
+//
+// onChange, if non-nil, is called after a real update commits so callers can
+// invalidate the subjectASTCache and bump a prepared-query ModifyIndex
+// without this package needing to import either.
+func UpdatePolicyIfChanged(ctx context.Context, store Store, policy Policy, onChange func()) (result Policy, changed bool, err error) {
+	existing, err := store.GetPolicyByID(ctx, policy.ID)
+	if err != nil {
+		return Policy{}, false, xerrors.Errorf("get existing policy: %w", err)
+	}
+
+	roleChanged := rbac.ShouldPersist(existing.Role.Hash(), policy.Role)
+	metadataChanged := existing.Name != policy.Name || existing.Description != policy.Description
+	if !roleChanged && !metadataChanged {
+		return existing, false, nil
+	}
+
+	updated, err := store.UpdatePolicy(ctx, policy)
+	if err != nil {
+		return Policy{}, false, xerrors.Errorf("update policy: %w", err)
+	}
+	if onChange != nil {
+		onChange()
+	}
+	return updated, true, nil
+}
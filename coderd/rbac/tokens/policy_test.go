@@ -0,0 +1,49 @@
+package tokens_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+func TestUpdatePolicyIfChangedSkipsTrueNoOp(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+
+	original, err := store.InsertPolicy(ctx, tokens.Policy{ID: "policy-1", Name: "reader", Description: "read-only", Role: role})
+	require.NoError(t, err)
+
+	_, changed, err := tokens.UpdatePolicyIfChanged(ctx, store, original, nil)
+	require.NoError(t, err)
+	require.False(t, changed, "resubmitting an identical policy must not persist a write")
+}
+
+func TestUpdatePolicyIfChangedPersistsMetadataOnlyEdit(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+
+	original, err := store.InsertPolicy(ctx, tokens.Policy{ID: "policy-1", Name: "reader", Description: "read-only", Role: role})
+	require.NoError(t, err)
+
+	edited := original
+	edited.Description = "read-only, renamed"
+
+	updated, changed, err := tokens.UpdatePolicyIfChanged(ctx, store, edited, nil)
+	require.NoError(t, err)
+	require.True(t, changed, "a Description edit must persist even when the role is unchanged")
+	require.Equal(t, "read-only, renamed", updated.Description)
+
+	stored, err := store.GetPolicyByID(ctx, "policy-1")
+	require.NoError(t, err)
+	require.Equal(t, "read-only, renamed", stored.Description)
+}
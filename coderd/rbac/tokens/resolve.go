@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+// ErrTokenExpired is returned by ResolveSubject for a token whose
+// expiration, if any, has passed.
+var ErrTokenExpired = xerrors.New("token expired")
+
+// ResolveSubject composes a rbac.Subject for the given token by expanding
+// every policy it links to into a single set of roles. The result is passed
+// straight into rbac.Subject.regoValue() by callers, so it participates in
+// subjectASTCache the same as any role-based subject: two tokens that
+// resolve to an identical subject share a cache entry.
+//
+// It rejects an expired token with ErrTokenExpired rather than trusting
+// callers to check Token.Expired themselves first.
+func ResolveSubject(ctx context.Context, store Store, token Token) (rbac.Subject, error) {
+	if token.Expired(time.Now()) {
+		return rbac.Subject{}, ErrTokenExpired
+	}
+
+	policies, err := store.PoliciesByIDs(ctx, token.PolicyIDs)
+	if err != nil {
+		return rbac.Subject{}, xerrors.Errorf("resolve policies: %w", err)
+	}
+	if len(policies) != len(token.PolicyIDs) {
+		return rbac.Subject{}, ErrPolicyNotFound
+	}
+
+	// Roles are identified by Policy.ID rather than Policy.Role.Name: role
+	// names are a display label a Policy author can reuse freely, while the
+	// Policy ID is the only thing guaranteed unique, so keying the registry
+	// on it avoids two distinct policies silently colliding on one entry.
+	roles := make(rbac.RoleIdentifiers, 0, len(policies))
+	for _, policy := range policies {
+		identifier := rbac.RoleIdentifier{Name: policy.ID}
+		rbac.RegisterRole(identifier, policy.Role)
+		roles = append(roles, identifier)
+	}
+
+	return rbac.Subject{
+		ID:    token.AccessorID,
+		Roles: roles,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package tokens_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+func TestResolveSubjectRejectsExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+	_, err := store.InsertPolicy(ctx, tokens.Policy{ID: "policy-1", Name: "reader", Role: role})
+	require.NoError(t, err)
+
+	expired, err := tokens.New("expiring token", []string{"policy-1"}, false, time.Minute, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	_, err = tokens.ResolveSubject(ctx, store, expired)
+	require.ErrorIs(t, err, tokens.ErrTokenExpired)
+}
+
+func TestResolveSubjectAcceptsLiveToken(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+	_, err := store.InsertPolicy(ctx, tokens.Policy{ID: "policy-1", Name: "reader", Role: role})
+	require.NoError(t, err)
+
+	live, err := tokens.New("live token", []string{"policy-1"}, false, time.Hour, time.Now())
+	require.NoError(t, err)
+
+	subject, err := tokens.ResolveSubject(ctx, store, live)
+	require.NoError(t, err)
+	require.Equal(t, live.AccessorID, subject.ID)
+}
+
+// TestResolveSubjectRolesExpand proves the resolved subject's roles are
+// actually usable for authorization, not just names: rbac.Subject.regoValue
+// (exercised on every authz check) calls RoleIdentifiers.Expand, which
+// previously always failed for every token-derived subject because
+// rbac.RoleByName had no registry to look the role up in.
+func TestResolveSubjectRolesExpand(t *testing.T) {
+	t.Parallel()
+
+	store := newMemStore()
+	ctx := context.Background()
+	role := rbac.Role{Name: "reader", Site: []rbac.Permission{{ResourceType: "workspace", Action: "read"}}}
+	_, err := store.InsertPolicy(ctx, tokens.Policy{ID: "policy-1", Name: "reader", Role: role})
+	require.NoError(t, err)
+
+	live, err := tokens.New("live token", []string{"policy-1"}, false, 0, time.Now())
+	require.NoError(t, err)
+
+	subject, err := tokens.ResolveSubject(ctx, store, live)
+	require.NoError(t, err)
+
+	expanded, err := subject.Roles.Expand()
+	require.NoError(t, err)
+	require.Equal(t, []rbac.Role{role}, expanded)
+}
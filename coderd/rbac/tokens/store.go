@@ -0,0 +1,30 @@
+package tokens
+
+import "context"
+
+// Store persists Tokens and Policies. Implementations are expected to wrap
+// a coderd/database.Store and enforce that Local tokens are excluded from
+// whatever replication mechanism the deployment uses.
+type Store interface {
+	InsertToken(ctx context.Context, token Token) (Token, error)
+	GetTokenByAccessorID(ctx context.Context, accessorID string) (Token, error)
+	UpdateToken(ctx context.Context, token Token) (Token, error)
+	DeleteToken(ctx context.Context, accessorID string) error
+	// ListReplicableTokens returns all non-local tokens, for replication to
+	// other replicas.
+	ListReplicableTokens(ctx context.Context) ([]Token, error)
+	// ListTokensByDescription returns every token (local or not) whose
+	// Description matches exactly. Used to find and revoke the prior
+	// bootstrap token, which is local and therefore absent from
+	// ListReplicableTokens.
+	ListTokensByDescription(ctx context.Context, description string) ([]Token, error)
+
+	InsertPolicy(ctx context.Context, policy Policy) (Policy, error)
+	GetPolicyByID(ctx context.Context, id string) (Policy, error)
+	UpdatePolicy(ctx context.Context, policy Policy) (Policy, error)
+	DeletePolicy(ctx context.Context, id string) error
+
+	// PoliciesByIDs resolves a token's PolicyIDs to their Policy values in a
+	// single round trip.
+	PoliciesByIDs(ctx context.Context, ids []string) ([]Policy, error)
+}
@@ -0,0 +1,39 @@
+package tokens_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tok, err := tokens.New("my token", []string{"policy-1"}, false, 0, now)
+	require.NoError(t, err)
+	require.NotEmpty(t, tok.AccessorID)
+	require.NotEmpty(t, tok.SecretID)
+	require.NotEqual(t, tok.AccessorID, tok.SecretID)
+	require.True(t, tok.ExpirationTime.IsZero())
+
+	other, err := tokens.New("my token", []string{"policy-1"}, false, 0, now)
+	require.NoError(t, err)
+	require.NotEqual(t, tok.AccessorID, other.AccessorID, "each token must get a unique accessor id")
+	require.NotEqual(t, tok.SecretID, other.SecretID, "each token must get a unique secret id")
+}
+
+func TestNewExpiring(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tok, err := tokens.New("expiring token", nil, true, time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, tok.ExpirationTTL)
+	require.Equal(t, now.Add(time.Hour), tok.ExpirationTime)
+	require.False(t, tok.Expired(now))
+	require.True(t, tok.Expired(now.Add(2*time.Hour)))
+}
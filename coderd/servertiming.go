@@ -0,0 +1,107 @@
+package coderd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type serverTimingKey struct{}
+
+type timingEntry struct {
+	name string
+	dur  time.Duration
+}
+
+type serverTiming struct {
+	mu      sync.Mutex
+	start   time.Time
+	entries []timingEntry
+}
+
+// ReportServerTiming records a named duration against the request in ctx so
+// it's included in the Server-Timing response header emitted by
+// ServerTimingMiddleware (and the JSON returned by LatencyCheckDetailed).
+// It's a no-op if ctx wasn't produced by that middleware, so callers like
+// the rbac authorizer and the DB layer can call it unconditionally without
+// checking whether the current request opted in.
+func ReportServerTiming(ctx context.Context, name string, dur time.Duration) {
+	st, ok := ctx.Value(serverTimingKey{}).(*serverTiming)
+	if !ok {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.entries = append(st.entries, timingEntry{name: name, dur: dur})
+}
+
+// ServerTimingMiddleware emits the Server-Timing response header with named
+// sub-measurements (authz, db, render, ...) reported via ReportServerTiming,
+// so browsers using the Resource Timing / Navigation Timing APIs can
+// attribute latency across the request pipeline rather than getting one
+// opaque number. Exposure is gated on the same allowed-origins list
+// LatencyCheck computes, via Timing-Allow-Origin.
+func ServerTimingMiddleware(allowAll bool, allowedOrigins ...*url.URL) func(http.Handler) http.Handler {
+	origins := timingAllowOrigins(allowAll, allowedOrigins)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			st := &serverTiming{start: time.Now()}
+			ctx := context.WithValue(r.Context(), serverTimingKey{}, st)
+
+			srw := &serverTimingResponseWriter{ResponseWriter: rw, timing: st, origins: origins}
+			next.ServeHTTP(srw, r.WithContext(ctx))
+		})
+	}
+}
+
+// serverTimingResponseWriter writes the Server-Timing header from whatever
+// has been reported so far, the moment the wrapped handler first writes a
+// header or body. Measurements reported after that point (there shouldn't
+// be any, in a well-behaved handler) are dropped, the same tradeoff any
+// header-based timing API makes once the response has started streaming.
+type serverTimingResponseWriter struct {
+	http.ResponseWriter
+	timing      *serverTiming
+	origins     string
+	wroteHeader bool
+}
+
+func (w *serverTimingResponseWriter) flushTiming() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	w.timing.mu.Lock()
+	parts := make([]string, 0, len(w.timing.entries)+1)
+	parts = append(parts, formatServerTimingEntry("total", time.Since(w.timing.start)))
+	for _, e := range w.timing.entries {
+		parts = append(parts, formatServerTimingEntry(e.name, e.dur))
+	}
+	w.timing.mu.Unlock()
+
+	if w.origins != "" {
+		w.Header().Set("Timing-Allow-Origin", w.origins)
+	}
+	w.Header().Set("Server-Timing", strings.Join(parts, ", "))
+}
+
+func (w *serverTimingResponseWriter) WriteHeader(status int) {
+	w.flushTiming()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *serverTimingResponseWriter) Write(b []byte) (int, error) {
+	w.flushTiming()
+	return w.ResponseWriter.Write(b)
+}
+
+// formatServerTimingEntry renders a single Server-Timing metric per
+// https://www.w3.org/TR/server-timing/, e.g. "db;dur=4.2".
+func formatServerTimingEntry(name string, dur time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.1f", name, float64(dur.Microseconds())/1000)
+}
@@ -0,0 +1,97 @@
+package coderd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/prepared"
+	"github.com/coder/coder/coderd/rbac/tokens"
+)
+
+// tokensHandler exposes CRUD over ACL tokens under /api/v2/tokens. It is a
+// thin wrapper around tokens.Store; request authorization is expected to be
+// enforced by the surrounding httpmw chain, same as every other coderd
+// route.
+//
+// broadcaster is bumped on every mutation so long-polling prepared-query
+// readers (see preparedqueries.go) wake up, and the subject AST cache is
+// invalidated for the same reason.
+type tokensHandler struct {
+	store       tokens.Store
+	broadcaster *prepared.Broadcaster
+}
+
+func newTokensHandler(store tokens.Store, broadcaster *prepared.Broadcaster) *tokensHandler {
+	return &tokensHandler{store: store, broadcaster: broadcaster}
+}
+
+func (h *tokensHandler) onMutate() {
+	rbac.InvalidateSubjectASTCache()
+	h.broadcaster.Bump()
+}
+
+func (h *tokensHandler) create(rw http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Description   string        `json:"description"`
+		PolicyIDs     []string      `json:"policy_ids"`
+		Local         bool          `json:"local"`
+		ExpirationTTL time.Duration `json:"expiration_ttl,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := tokens.New(req.Description, req.PolicyIDs, req.Local, req.ExpirationTTL, time.Now())
+	if err != nil {
+		httpError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	created, err := h.store.InsertToken(r.Context(), token)
+	if err != nil {
+		httpError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	h.onMutate()
+	// Token.SecretID is never marshaled, so this is the one response that
+	// hands the bearer secret back to the caller.
+	httpJSON(rw, http.StatusCreated, struct {
+		tokens.Token
+		SecretID string `json:"secret_id"`
+	}{created, token.SecretID})
+}
+
+func (h *tokensHandler) get(rw http.ResponseWriter, r *http.Request) {
+	accessorID := chi.URLParam(r, "accessorID")
+	token, err := h.store.GetTokenByAccessorID(r.Context(), accessorID)
+	if err != nil {
+		httpError(rw, http.StatusNotFound, err)
+		return
+	}
+	httpJSON(rw, http.StatusOK, token)
+}
+
+func (h *tokensHandler) delete(rw http.ResponseWriter, r *http.Request) {
+	accessorID := chi.URLParam(r, "accessorID")
+	if err := h.store.DeleteToken(r.Context(), accessorID); err != nil {
+		httpError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	h.onMutate()
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func httpJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(v)
+}
+
+func httpError(rw http.ResponseWriter, status int, err error) {
+	httpJSON(rw, status, map[string]string{"detail": err.Error()})
+}